@@ -0,0 +1,43 @@
+package awsauth
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+// newSTSClient is a seam so tests can point AssumeRoleWithWebIdentity at a
+// local httptest.Server instead of the real STS endpoint.
+var newSTSClient = func() (*sts.STS, error) {
+	sess, err := session.NewSession()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return sts.New(sess), nil
+}
+
+// AssumeRoleWithWebIdentity trades an OIDC id_token (from
+// okta.AwsOidcLogin) for short-lived AWS credentials, as an alternative
+// to the SAML assume-role path for orgs that configure AWS IAM with an
+// OIDC identity provider.
+func AssumeRoleWithWebIdentity(roleArn string, idToken string) (*sts.Credentials, error) {
+	svc, err := newSTSClient()
+
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := svc.AssumeRoleWithWebIdentity(&sts.AssumeRoleWithWebIdentityInput{
+		RoleArn:          aws.String(roleArn),
+		RoleSessionName:  aws.String("yak"),
+		WebIdentityToken: aws.String(idToken),
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Credentials, nil
+}
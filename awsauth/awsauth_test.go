@@ -0,0 +1,83 @@
+package awsauth
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/credentials"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/sts"
+)
+
+const assumeRoleResponseTemplate = `<AssumeRoleWithWebIdentityResponse xmlns="https://sts.amazonaws.com/doc/2011-06-15/">
+  <AssumeRoleWithWebIdentityResult>
+    <Credentials>
+      <AccessKeyId>AKIAEXAMPLE</AccessKeyId>
+      <SecretAccessKey>secretExample</SecretAccessKey>
+      <SessionToken>tokenExample</SessionToken>
+      <Expiration>2026-01-01T00:00:00Z</Expiration>
+    </Credentials>
+  </AssumeRoleWithWebIdentityResult>
+  <ResponseMetadata>
+    <RequestId>request-id</RequestId>
+  </ResponseMetadata>
+</AssumeRoleWithWebIdentityResponse>`
+
+func useTestSTSServer(t *testing.T, handler http.HandlerFunc) {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	original := newSTSClient
+
+	newSTSClient = func() (*sts.STS, error) {
+		sess, err := session.NewSession(&aws.Config{
+			Region:      aws.String("us-east-1"),
+			Endpoint:    aws.String(server.URL),
+			Credentials: credentials.AnonymousCredentials,
+		})
+
+		if err != nil {
+			return nil, err
+		}
+
+		return sts.New(sess), nil
+	}
+
+	t.Cleanup(func() { newSTSClient = original })
+}
+
+func TestAssumeRoleWithWebIdentityReturnsCredentials(t *testing.T) {
+	useTestSTSServer(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, assumeRoleResponseTemplate)
+	})
+
+	creds, err := AssumeRoleWithWebIdentity("arn:aws:iam::123456789012:role/example", "fake-id-token")
+
+	if err != nil {
+		t.Fatalf("AssumeRoleWithWebIdentity returned error: %v", err)
+	}
+
+	if aws.StringValue(creds.AccessKeyId) != "AKIAEXAMPLE" {
+		t.Errorf("expected AccessKeyId %q, got %q", "AKIAEXAMPLE", aws.StringValue(creds.AccessKeyId))
+	}
+
+	if aws.StringValue(creds.SessionToken) != "tokenExample" {
+		t.Errorf("expected SessionToken %q, got %q", "tokenExample", aws.StringValue(creds.SessionToken))
+	}
+}
+
+func TestAssumeRoleWithWebIdentityReturnsErrorOnFailure(t *testing.T) {
+	useTestSTSServer(t, func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadRequest)
+		fmt.Fprint(w, `<ErrorResponse><Error><Code>InvalidIdentityToken</Code><Message>bad token</Message></Error></ErrorResponse>`)
+	})
+
+	if _, err := AssumeRoleWithWebIdentity("arn:aws:iam::123456789012:role/example", "bad-token"); err == nil {
+		t.Error("expected an error for a failed assume-role call, got nil")
+	}
+}
@@ -0,0 +1,84 @@
+package azuread
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/redbubble/yak/idp"
+)
+
+func TestAuthenticateDecodesAccessTokenFromJSON(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(tokenResponse{
+			AccessToken: "eyJ.fake.token",
+			TokenType:   "Bearer",
+			ExpiresIn:   3600,
+		})
+	}))
+	defer server.Close()
+
+	provider := &Provider{TenantID: "tenant", ClientID: "client", tokenURLOverride: server.URL}
+
+	session, err := provider.Authenticate(idp.UserData{Username: "alice", Password: "hunter2"})
+
+	if err != nil {
+		t.Fatalf("Authenticate returned error: %v", err)
+	}
+
+	if session.SessionToken != "eyJ.fake.token" {
+		t.Errorf("expected SessionToken %q, got %q", "eyJ.fake.token", session.SessionToken)
+	}
+
+	if session.Status != "SUCCESS" {
+		t.Errorf("expected Status SUCCESS, got %q", session.Status)
+	}
+}
+
+func TestAuthenticateRejectsResponseWithNoAccessToken(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(tokenResponse{TokenType: "Bearer"})
+	}))
+	defer server.Close()
+
+	provider := &Provider{TenantID: "tenant", ClientID: "client", tokenURLOverride: server.URL}
+
+	if _, err := provider.Authenticate(idp.UserData{Username: "alice", Password: "hunter2"}); err == nil {
+		t.Error("expected an error for a response with no access_token, got nil")
+	}
+}
+
+func TestAuthenticateRejectsNonSuccessStatus(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusUnauthorized)
+		json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+	}))
+	defer server.Close()
+
+	provider := &Provider{TenantID: "tenant", ClientID: "client", tokenURLOverride: server.URL}
+
+	if _, err := provider.Authenticate(idp.UserData{Username: "alice", Password: "wrong"}); err == nil {
+		t.Error("expected an error for a non-2xx response, got nil")
+	}
+}
+
+func TestFetchSAMLAssertionSendsBearerToken(t *testing.T) {
+	var gotAuthHeader string
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotAuthHeader = r.Header.Get("Authorization")
+		w.Write([]byte("<saml/>"))
+	}))
+	defer server.Close()
+
+	provider := &Provider{TenantID: "tenant", ClientID: "client"}
+
+	if _, err := provider.FetchSAMLAssertion(idp.Session{SessionToken: "eyJ.fake.token"}, server.URL); err != nil {
+		t.Fatalf("FetchSAMLAssertion returned error: %v", err)
+	}
+
+	if gotAuthHeader != "Bearer eyJ.fake.token" {
+		t.Errorf("expected Authorization header %q, got %q", "Bearer eyJ.fake.token", gotAuthHeader)
+	}
+}
@@ -0,0 +1,76 @@
+package idp
+
+import (
+	"time"
+
+	"github.com/redbubble/yak/session"
+)
+
+// oktaSessionLifetime matches Okta's default sessionToken lifetime; yak
+// has no way to learn the real expiry from the AuthN response, so it
+// errs on the short side rather than replaying a token Okta has already
+// expired.
+const oktaSessionLifetime = 55 * time.Minute
+
+// AuthenticateWithCache wraps Provider.Authenticate with a session.Store
+// lookup, so yak only runs primary auth once per cache lifetime instead
+// of on every invocation. For MFA-enabled accounts this returns a
+// MFA_REQUIRED Session exactly like Provider.Authenticate does — the
+// session is only actually cached once ChallengeMFAWithCache reports
+// SUCCESS, since an uncompleted MFA challenge isn't a usable session.
+func AuthenticateWithCache(store session.Store, profile string, provider Provider, userData UserData) (Session, error) {
+	if cached, err := store.Load(profile); err == nil && cached != nil && !cached.Expired() {
+		return Session{SessionToken: cached.OktaSessionToken, Status: "SUCCESS"}, nil
+	}
+
+	sess, err := provider.Authenticate(userData)
+
+	if err != nil {
+		return sess, err
+	}
+
+	if sess.Status == "SUCCESS" {
+		saveOktaSession(store, profile, sess.SessionToken)
+	}
+
+	return sess, nil
+}
+
+// ChallengeMFAWithCache wraps Provider.ChallengeMFA the same way
+// AuthenticateWithCache wraps Provider.Authenticate: on a SUCCESS result
+// it's the post-MFA session that actually gets cached, since that's the
+// first point an MFA-enabled account has a usable session token at all.
+func ChallengeMFAWithCache(store session.Store, profile string, provider Provider, sess Session, factor Factor) (Session, error) {
+	result, err := provider.ChallengeMFA(sess, factor)
+
+	if err != nil {
+		return result, err
+	}
+
+	if result.Status == "SUCCESS" {
+		saveOktaSession(store, profile, result.SessionToken)
+	}
+
+	return result, nil
+}
+
+// saveOktaSession merges the session token into whatever's already
+// cached for this profile, rather than overwriting it outright, so it
+// doesn't clobber a SAML-cookie or AWS-credential tier saved separately
+// (see idp/okta.Adapter.LoginWithCache).
+func saveOktaSession(store session.Store, profile string, sessionToken string) {
+	if sessionToken == "" {
+		return
+	}
+
+	cached, err := store.Load(profile)
+
+	if err != nil || cached == nil {
+		cached = &session.Session{}
+	}
+
+	cached.OktaSessionToken = sessionToken
+	cached.ExpiresAt = time.Now().Add(oktaSessionLifetime)
+
+	store.Save(profile, cached)
+}
@@ -0,0 +1,95 @@
+package okta
+
+import (
+	"context"
+	"errors"
+	"os"
+
+	oktasdk "github.com/okta/okta-sdk-golang/v2/okta"
+)
+
+// APITokenClient is the API-token-mode discovery client: it uses the
+// official SDK's SSWS auth to talk to the management API (factors, app
+// links) instead of prompting for a password. It deliberately does NOT
+// implement the Client interface: SSWS auth proves the *caller* holds an
+// admin API token, never that the end user supplied a password or
+// completed MFA, so it must never be usable as a drop-in replacement for
+// AuthNClient in the primary-auth/MFA path. Its methods are read-only
+// discovery against a user that's already been identified elsewhere.
+type APITokenClient struct {
+	sdk *oktasdk.Client
+}
+
+// NewAPITokenClient builds an APITokenClient from OKTA_API_TOKEN (or the
+// token passed in directly).
+func NewAPITokenClient(orgURL string, apiToken string) (*APITokenClient, error) {
+	if apiToken == "" {
+		apiToken = os.Getenv("OKTA_API_TOKEN")
+	}
+
+	if apiToken == "" {
+		return nil, errors.New("no Okta API token configured (set OKTA_API_TOKEN)")
+	}
+
+	_, sdkClient, err := oktasdk.NewClient(
+		context.Background(),
+		oktasdk.WithOrgUrl(orgURL),
+		oktasdk.WithToken(apiToken),
+	)
+
+	if err != nil {
+		return nil, translateSdkError(err)
+	}
+
+	return &APITokenClient{sdk: sdkClient}, nil
+}
+
+// ListFactors returns the factors already enrolled against userID, for
+// automatic discovery of what MFA options a profile's real
+// password-based login will be able to use. It does not verify or
+// enroll anything.
+func (c *APITokenClient) ListFactors(userID string) ([]oktasdk.Factor, error) {
+	factors, _, err := c.sdk.UserFactor.ListFactors(context.Background(), userID)
+
+	if err != nil {
+		return nil, translateSdkError(err)
+	}
+
+	return factors, nil
+}
+
+// ListAwsAppLinks discovers the AWS app URLs assigned to userID, so yak
+// can pick a profile's SAML/OIDC entry point without the user having to
+// paste it into their config by hand.
+func (c *APITokenClient) ListAwsAppLinks(userID string) ([]string, error) {
+	links, _, err := c.sdk.User.ListAppLinks(context.Background(), userID)
+
+	if err != nil {
+		return nil, translateSdkError(err)
+	}
+
+	var hrefs []string
+
+	for _, link := range links {
+		if link.AppName == "amazon_aws" {
+			hrefs = append(hrefs, link.LinkUrl)
+		}
+	}
+
+	return hrefs, nil
+}
+
+// translateSdkError maps the SDK's typed okta.Error onto yak's existing
+// error surface, so callers don't need their own type switch.
+func translateSdkError(err error) error {
+	if sdkErr, ok := err.(*oktasdk.Error); ok {
+		switch sdkErr.ErrorCode {
+		case "E0000011", "E0000004":
+			return errors.New("Unauthorised (" + sdkErr.ErrorSummary + ")")
+		default:
+			return errors.New(sdkErr.ErrorSummary)
+		}
+	}
+
+	return err
+}
@@ -0,0 +1,92 @@
+package okta
+
+import (
+	"errors"
+
+	"github.com/redbubble/yak/idp"
+	"github.com/redbubble/yak/session"
+)
+
+// LoginWithCache drives the full Okta+SAML+AWS login through session.Store,
+// so that of the three things yak would otherwise redo on every
+// invocation — primary auth, the SAML cookie exchange, and the
+// assume-role call — only the tiers that actually expired get redone:
+//
+//   - valid cached AWS credentials: skip everything, return them directly
+//   - valid cached Okta session: skip Authenticate/ChallengeMFA, replay
+//     the cached SAML cookies straight into AwsSamlLoginWithCookies
+//   - otherwise: run primary auth (and MFA, if required) from scratch
+//
+// assumeRole does the actual SAML-to-STS exchange; it's a callback
+// rather than a direct aws-sdk-go call because that wiring belongs to
+// whatever eventually calls this (there's no cmd package in this tree to
+// own it yet).
+func (a *Adapter) LoginWithCache(store session.Store, profile string, userData idp.UserData, mfaFactor *idp.Factor, appURL string, assumeRole func(samlAssertion string) (session.AwsCredentials, error)) (session.AwsCredentials, error) {
+	cached, err := store.Load(profile)
+
+	if err != nil {
+		return session.AwsCredentials{}, err
+	}
+
+	if cached == nil {
+		cached = &session.Session{}
+	}
+
+	if cached.AwsCredentials.AccessKeyID != "" && !cached.AwsCredentials.Expired() {
+		return cached.AwsCredentials, nil
+	}
+
+	sess := idp.Session{SessionToken: cached.OktaSessionToken, Status: "SUCCESS"}
+
+	if cached.OktaSessionToken == "" || cached.Expired() {
+		sess, err = idp.AuthenticateWithCache(store, profile, a, userData)
+
+		if err != nil {
+			return session.AwsCredentials{}, err
+		}
+
+		if sess.Status != "SUCCESS" {
+			if mfaFactor == nil {
+				return session.AwsCredentials{}, errors.New("MFA required but no factor was supplied")
+			}
+
+			sess, err = idp.ChallengeMFAWithCache(store, profile, a, sess, *mfaFactor)
+
+			if err != nil {
+				return session.AwsCredentials{}, err
+			}
+		}
+
+		cached, err = store.Load(profile)
+
+		if err != nil {
+			return session.AwsCredentials{}, err
+		}
+
+		if cached == nil {
+			cached = &session.Session{OktaSessionToken: sess.SessionToken}
+		}
+	}
+
+	saml, cookies, err := AwsSamlLoginWithCookies(a.OktaHref, appURL, OktaAuthResponse{SessionToken: sess.SessionToken}, cached.Cookies)
+
+	if err != nil {
+		return session.AwsCredentials{}, err
+	}
+
+	cached.Cookies = cookies
+
+	creds, err := assumeRole(saml)
+
+	if err != nil {
+		return session.AwsCredentials{}, err
+	}
+
+	cached.AwsCredentials = creds
+
+	if err := store.Save(profile, cached); err != nil {
+		return session.AwsCredentials{}, err
+	}
+
+	return creds, nil
+}
@@ -0,0 +1,73 @@
+package okta
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+// Client abstracts the two ways yak can talk to Okta: the public,
+// unauthenticated AuthN API that the interactive password flow uses, and
+// the authenticated management API (via the official SDK) that API-token
+// mode uses to discover factors and app links without a user password.
+type Client interface {
+	PrimaryAuth(userData UserData) (OktaAuthResponse, error)
+	VerifyFactor(url string, body interface{}) (OktaAuthResponse, error)
+	PollFactor(url string, body interface{}) (OktaAuthResponse, error)
+	SessionToken() string
+}
+
+// AuthNClient is the default Client, backed by the same /api/v1/authn
+// endpoints yak has always used.
+type AuthNClient struct {
+	OktaHref string
+	session  string
+}
+
+// AuthNClient must keep satisfying Client. APITokenClient deliberately
+// does not (see its doc comment) and must never be made to by accident.
+var _ Client = (*AuthNClient)(nil)
+
+func NewAuthNClient(oktaHref string) *AuthNClient {
+	return &AuthNClient{OktaHref: oktaHref}
+}
+
+func (c *AuthNClient) PrimaryAuth(userData UserData) (OktaAuthResponse, error) {
+	resp, err := Authenticate(c.OktaHref, userData)
+
+	if err == nil {
+		c.session = resp.SessionToken
+	}
+
+	return resp, err
+}
+
+func (c *AuthNClient) VerifyFactor(url string, body interface{}) (OktaAuthResponse, error) {
+	payload, err := json.Marshal(body)
+
+	if err != nil {
+		return OktaAuthResponse{YakStatusCode: YAK_STATUS_DATA_ERROR}, err
+	}
+
+	respBody, yakStatus, err := makeRequest(url, bytes.NewBuffer(payload))
+
+	if err != nil {
+		return OktaAuthResponse{YakStatusCode: yakStatus}, err
+	}
+
+	authResponse := OktaAuthResponse{YakStatusCode: YAK_STATUS_OK}
+	json.Unmarshal(respBody, &authResponse)
+
+	if authResponse.SessionToken != "" {
+		c.session = authResponse.SessionToken
+	}
+
+	return authResponse, nil
+}
+
+func (c *AuthNClient) PollFactor(url string, body interface{}) (OktaAuthResponse, error) {
+	return c.VerifyFactor(url, body)
+}
+
+func (c *AuthNClient) SessionToken() string {
+	return c.session
+}
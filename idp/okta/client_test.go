@@ -0,0 +1,19 @@
+package okta
+
+import "testing"
+
+func TestAuthNClientSatisfiesClient(t *testing.T) {
+	if _, ok := interface{}(&AuthNClient{}).(Client); !ok {
+		t.Fatal("expected *AuthNClient to satisfy Client")
+	}
+}
+
+// TestAPITokenClientDoesNotSatisfyClient guards against the auth bypass
+// this package used to have: APITokenClient proves the caller holds an
+// admin API token, never that the end user supplied a password or
+// completed MFA, so it must never satisfy Client.
+func TestAPITokenClientDoesNotSatisfyClient(t *testing.T) {
+	if _, ok := interface{}(&APITokenClient{}).(Client); ok {
+		t.Fatal("expected *APITokenClient to NOT satisfy Client")
+	}
+}
@@ -0,0 +1,93 @@
+package okta
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+)
+
+func encodeECKey(t *testing.T, curve elliptic.Curve) []byte {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(curve, rand.Reader)
+
+	if err != nil {
+		t.Fatalf("could not generate EC key: %v", err)
+	}
+
+	der, err := x509.MarshalECPrivateKey(key)
+
+	if err != nil {
+		t.Fatalf("could not marshal EC key: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der})
+}
+
+func encodeRSAKey(t *testing.T) []byte {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+
+	if err != nil {
+		t.Fatalf("could not generate RSA key: %v", err)
+	}
+
+	return pem.EncodeToMemory(&pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(key)})
+}
+
+func TestParseSigningKeyP256(t *testing.T) {
+	_, alg, err := parseSigningKey(encodeECKey(t, elliptic.P256()))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if alg != "ES256" {
+		t.Errorf("expected ES256, got %s", alg)
+	}
+}
+
+func TestParseSigningKeyRejectsNonP256Curves(t *testing.T) {
+	_, _, err := parseSigningKey(encodeECKey(t, elliptic.P384()))
+
+	if err == nil {
+		t.Fatal("expected an error for a P-384 key, got none")
+	}
+}
+
+func TestParseSigningKeyRSA(t *testing.T) {
+	_, alg, err := parseSigningKey(encodeRSAKey(t))
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if alg != "RS256" {
+		t.Errorf("expected RS256, got %s", alg)
+	}
+}
+
+func TestBuildClientAssertionProducesAWellFormedJWT(t *testing.T) {
+	cfg := JWTAuthConfig{
+		ClientID:      "client-id",
+		KeyID:         "key-id",
+		TokenURL:      "https://example.okta.com/oauth2/v1/token",
+		PrivateKeyPEM: encodeRSAKey(t),
+	}
+
+	assertion, err := buildClientAssertion(cfg)
+
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if parts := strings.Split(assertion, "."); len(parts) != 3 {
+		t.Fatalf("expected a 3-part JWT, got %d parts", len(parts))
+	}
+}
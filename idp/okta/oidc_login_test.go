@@ -0,0 +1,86 @@
+package okta
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func fakeIDToken(t *testing.T, aud string) string {
+	t.Helper()
+
+	header := base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"none","typ":"JWT"}`))
+	claims, err := json.Marshal(map[string]string{"aud": aud})
+
+	if err != nil {
+		t.Fatalf("could not marshal claims: %v", err)
+	}
+
+	payload := base64.RawURLEncoding.EncodeToString(claims)
+
+	return header + "." + payload + ".sig"
+}
+
+func newOidcTestServer(t *testing.T, idToken string) *httptest.Server {
+	t.Helper()
+
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("/oauth2/default/v1/authorize", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://localhost:8080/callback?code=fake-code&state=yak", http.StatusFound)
+	})
+
+	mux.HandleFunc("/oauth2/default/v1/token", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(oidcTokenResponse{IDToken: idToken, TokenType: "Bearer", ExpiresIn: 3600})
+	})
+
+	return httptest.NewServer(mux)
+}
+
+func TestAwsOidcLoginReturnsIdTokenWhenAudienceMatches(t *testing.T) {
+	server := newOidcTestServer(t, fakeIDToken(t, "urn:amazon:webservices"))
+	defer server.Close()
+
+	idToken, err := AwsOidcLogin(server.URL, "client-id", "urn:amazon:webservices", OktaAuthResponse{SessionToken: "session-token"})
+
+	if err != nil {
+		t.Fatalf("AwsOidcLogin returned error: %v", err)
+	}
+
+	if idToken == "" {
+		t.Error("expected a non-empty id_token")
+	}
+}
+
+func TestAwsOidcLoginRejectsAudienceMismatch(t *testing.T) {
+	server := newOidcTestServer(t, fakeIDToken(t, "some-other-audience"))
+	defer server.Close()
+
+	_, err := AwsOidcLogin(server.URL, "client-id", "urn:amazon:webservices", OktaAuthResponse{SessionToken: "session-token"})
+
+	if err == nil {
+		t.Fatal("expected an error for a mismatched audience, got nil")
+	}
+
+	if !strings.Contains(err.Error(), "audience") {
+		t.Errorf("expected an audience-related error, got: %v", err)
+	}
+}
+
+func TestAwsOidcLoginSkipsAudienceCheckWhenNotRequested(t *testing.T) {
+	server := newOidcTestServer(t, fakeIDToken(t, "whatever"))
+	defer server.Close()
+
+	idToken, err := AwsOidcLogin(server.URL, "client-id", "", OktaAuthResponse{SessionToken: "session-token"})
+
+	if err != nil {
+		t.Fatalf("AwsOidcLogin returned error: %v", err)
+	}
+
+	if idToken == "" {
+		t.Error("expected a non-empty id_token")
+	}
+}
@@ -58,6 +58,44 @@ type AuthResponseEmbedded struct {
 	Factors []AuthResponseFactor `json:"factors"`
 }
 
+type WebAuthnRequest struct {
+	StateToken string `json:"stateToken"`
+}
+
+type WebAuthnFactorProfile struct {
+	CredentialID string `json:"credentialId"`
+}
+
+type WebAuthnChallengeEmbeddedFactor struct {
+	Profile WebAuthnFactorProfile `json:"profile"`
+}
+
+type WebAuthnChallengeEmbedded struct {
+	Factor WebAuthnChallengeEmbeddedFactor `json:"factor"`
+}
+
+type WebAuthnChallengeResponse struct {
+	StateToken string                    `json:"stateToken"`
+	Challenge  string                    `json:"challenge"`
+	RpId       string                    `json:"rpId"`
+	Embedded   WebAuthnChallengeEmbedded `json:"_embedded"`
+}
+
+type WebAuthnAssertion struct {
+	StateToken        string `json:"stateToken"`
+	CredentialID      string `json:"credentialId"`
+	ClientData        string `json:"clientData"`
+	AuthenticatorData string `json:"authenticatorData"`
+	SignatureData     string `json:"signatureData"`
+}
+
+// Authenticator wraps whatever local CTAP2/U2F HID library yak is built
+// with, so this package can be compiled without CGO on platforms that
+// can't talk to a security key.
+type Authenticator interface {
+	Sign(challenge string, rpId string, credentialID string) (WebAuthnAssertion, error)
+}
+
 const (
 	YAK_STATUS_OK           = iota
 	YAK_STATUS_UNAUTHORISED = iota
@@ -173,17 +211,70 @@ func VerifyPush(url string, pushRequestBody PushRequest) (OktaAuthResponse, erro
 	}
 }
 
+func VerifyWebAuthn(url string, webAuthnRequestBody WebAuthnRequest, authenticator Authenticator) (OktaAuthResponse, error) {
+	requestJson, err := json.Marshal(webAuthnRequestBody)
+
+	if err != nil {
+		return OktaAuthResponse{YakStatusCode: YAK_STATUS_DATA_ERROR}, err
+	}
+
+	body, yakStatus, err := makeRequest(url, bytes.NewBuffer(requestJson))
+
+	if err != nil {
+		return OktaAuthResponse{YakStatusCode: yakStatus}, err
+	}
+
+	challenge := WebAuthnChallengeResponse{}
+	json.Unmarshal(body, &challenge)
+
+	assertion, err := authenticator.Sign(challenge.Challenge, challenge.RpId, challenge.Embedded.Factor.Profile.CredentialID)
+
+	if err != nil {
+		return OktaAuthResponse{YakStatusCode: YAK_STATUS_DATA_ERROR}, err
+	}
+
+	assertion.StateToken = webAuthnRequestBody.StateToken
+
+	assertionJson, err := json.Marshal(assertion)
+
+	if err != nil {
+		return OktaAuthResponse{YakStatusCode: YAK_STATUS_DATA_ERROR}, err
+	}
+
+	body, yakStatus, err = makeRequest(url, bytes.NewBuffer(assertionJson))
+
+	if err != nil {
+		return OktaAuthResponse{YakStatusCode: yakStatus}, err
+	}
+
+	authResponse := OktaAuthResponse{YakStatusCode: YAK_STATUS_OK}
+	json.Unmarshal(body, &authResponse)
+
+	return authResponse, nil
+}
+
 func AwsSamlLogin(oktaHref string, samlHref string, oktaAuthResponse OktaAuthResponse) (string, error) {
+	saml, _, err := AwsSamlLoginWithCookies(oktaHref, samlHref, oktaAuthResponse, nil)
+
+	return saml, err
+}
+
+// AwsSamlLoginWithCookies is AwsSamlLogin plus the cookies the Okta
+// session picked up along the way. Passing in cookies from a previous
+// call (e.g. loaded from session.Store) lets the request replay an
+// existing Okta session instead of starting fresh; the returned cookies
+// are what a caller should persist for next time.
+func AwsSamlLoginWithCookies(oktaHref string, samlHref string, oktaAuthResponse OktaAuthResponse, cookies []*http.Cookie) (string, []*http.Cookie, error) {
 	oktaUrl, err := url.Parse(oktaHref)
 
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	samlEndpoint, err := url.Parse(samlHref)
 
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	samlUrl := oktaUrl.ResolveReference(samlEndpoint)
@@ -196,7 +287,11 @@ func AwsSamlLogin(oktaHref string, samlHref string, oktaAuthResponse OktaAuthRes
 	jar, err := cookiejar.New(nil)
 
 	if err != nil {
-		return "", err
+		return "", nil, err
+	}
+
+	if len(cookies) > 0 {
+		jar.SetCookies(samlUrl, cookies)
 	}
 
 	client := http.Client{
@@ -206,30 +301,30 @@ func AwsSamlLogin(oktaHref string, samlHref string, oktaAuthResponse OktaAuthRes
 	resp, err := client.Get(samlUrl.String())
 
 	if err != nil {
-		return "", err
+		return "", nil, err
 	} else if resp.StatusCode >= 300 {
-		return "", errors.New("Could not get SAML payload" + resp.Status + ")")
+		return "", nil, errors.New("Could not get SAML payload" + resp.Status + ")")
 	}
 
 	body, err := ioutil.ReadAll(resp.Body)
 
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	data, err := extractSamlPayload(body)
 
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
 	saml, err := base64.StdEncoding.DecodeString(data)
 
 	if err != nil {
-		return "", err
+		return "", nil, err
 	}
 
-	return string(saml), nil
+	return string(saml), jar.Cookies(samlUrl), nil
 }
 
 func makeRequest(url string, body io.Reader) ([]byte, int, error) {
@@ -291,10 +386,12 @@ func extractSamlPayload(htmlDocument []byte) (string, error) {
 	return data, nil
 }
 
-func TotpFactorName(key string) string {
+func FactorName(key string) string {
 	switch key {
 	case "GOOGLE":
 		return "Google Authenticator"
+	case "webauthn", "u2f":
+		return "Security Key or Biometric"
 	default:
 		return key
 	}
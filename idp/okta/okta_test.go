@@ -0,0 +1,98 @@
+package okta
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+type fakeAuthenticator struct {
+	gotChallenge    string
+	gotRpId         string
+	gotCredentialID string
+}
+
+func (f *fakeAuthenticator) Sign(challenge string, rpId string, credentialID string) (WebAuthnAssertion, error) {
+	f.gotChallenge = challenge
+	f.gotRpId = rpId
+	f.gotCredentialID = credentialID
+
+	return WebAuthnAssertion{
+		CredentialID:      credentialID,
+		ClientData:        "client-data",
+		AuthenticatorData: "authenticator-data",
+		SignatureData:     "signature-data",
+	}, nil
+}
+
+func TestVerifyWebAuthn(t *testing.T) {
+	var verifyRequests int
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		verifyRequests++
+
+		if verifyRequests == 1 {
+			json.NewEncoder(w).Encode(WebAuthnChallengeResponse{
+				Challenge: "the-challenge",
+				RpId:      "example.okta.com",
+				Embedded: WebAuthnChallengeEmbedded{
+					Factor: WebAuthnChallengeEmbeddedFactor{
+						Profile: WebAuthnFactorProfile{CredentialID: "cred-123"},
+					},
+				},
+			})
+
+			return
+		}
+
+		var assertion WebAuthnAssertion
+
+		if err := json.NewDecoder(r.Body).Decode(&assertion); err != nil {
+			t.Fatalf("could not decode assertion: %v", err)
+		}
+
+		if assertion.CredentialID != "cred-123" {
+			t.Errorf("expected credentialId cred-123, got %s", assertion.CredentialID)
+		}
+
+		if assertion.StateToken != "state-token" {
+			t.Errorf("expected stateToken to be carried through, got %s", assertion.StateToken)
+		}
+
+		json.NewEncoder(w).Encode(OktaAuthResponse{Status: "SUCCESS", SessionToken: "session-token"})
+	}))
+
+	defer server.Close()
+
+	fake := &fakeAuthenticator{}
+
+	resp, err := VerifyWebAuthn(server.URL, WebAuthnRequest{StateToken: "state-token"}, fake)
+
+	if err != nil {
+		t.Fatalf("VerifyWebAuthn returned error: %v", err)
+	}
+
+	if resp.Status != "SUCCESS" || resp.SessionToken != "session-token" {
+		t.Errorf("unexpected auth response: %+v", resp)
+	}
+
+	if fake.gotChallenge != "the-challenge" || fake.gotRpId != "example.okta.com" || fake.gotCredentialID != "cred-123" {
+		t.Errorf("authenticator was not called with the challenge payload: %+v", fake)
+	}
+}
+
+func TestFactorName(t *testing.T) {
+	cases := map[string]string{
+		"GOOGLE":   "Google Authenticator",
+		"webauthn": "Security Key or Biometric",
+		"u2f":      "Security Key or Biometric",
+		"SMS":      "SMS",
+	}
+
+	for key, expected := range cases {
+		if got := FactorName(key); got != expected {
+			t.Errorf("FactorName(%q) = %q, want %q", key, got, expected)
+		}
+	}
+}
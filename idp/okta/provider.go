@@ -0,0 +1,85 @@
+package okta
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/redbubble/yak/idp"
+)
+
+// Adapter implements idp.Provider on top of the Okta-specific functions in
+// this package, so the generic SAML/AWS login path in yak's cmd package
+// doesn't need to know it's talking to Okta.
+type Adapter struct {
+	OktaHref string
+}
+
+func NewAdapter(oktaHref string) *Adapter {
+	return &Adapter{OktaHref: oktaHref}
+}
+
+func (a *Adapter) Authenticate(userData idp.UserData) (idp.Session, error) {
+	resp, err := Authenticate(a.OktaHref, UserData{Username: userData.Username, Password: userData.Password})
+
+	if err != nil {
+		return idp.Session{}, err
+	}
+
+	return toSession(resp), nil
+}
+
+func (a *Adapter) ChallengeMFA(session idp.Session, factor idp.Factor) (idp.Session, error) {
+	switch factor.Type {
+	case "push":
+		resp, err := VerifyPush(factor.VerifyLink, PushRequest{StateToken: session.StateToken})
+
+		if err != nil {
+			return idp.Session{}, err
+		}
+
+		return toSession(resp), nil
+	case "token:software:totp", "token:hardware", "sms", "call":
+		if factor.Credential == "" {
+			return idp.Session{}, errors.New("factor.Credential must carry the passcode for a " + factor.Type + " factor")
+		}
+
+		resp, err := VerifyTotp(factor.VerifyLink, TotpRequest{StateToken: session.StateToken, PassCode: factor.Credential})
+
+		if err != nil {
+			return idp.Session{}, err
+		}
+
+		return toSession(resp), nil
+	case "webauthn", "u2f":
+		return idp.Session{}, errors.New("webauthn/u2f factors need a local Authenticator; call VerifyWebAuthn directly instead of going through the generic Provider")
+	default:
+		return idp.Session{}, fmt.Errorf("unsupported factor type: %s", factor.Type)
+	}
+}
+
+func (a *Adapter) FetchSAMLAssertion(session idp.Session, appURL string) (string, error) {
+	if session.SessionToken == "" {
+		return "", errors.New("no Okta session token to exchange for a SAML assertion")
+	}
+
+	return AwsSamlLogin(a.OktaHref, appURL, OktaAuthResponse{SessionToken: session.SessionToken})
+}
+
+func toSession(resp OktaAuthResponse) idp.Session {
+	factors := make([]idp.Factor, 0, len(resp.Embedded.Factors))
+
+	for _, f := range resp.Embedded.Factors {
+		factors = append(factors, idp.Factor{
+			Type:       f.FactorType,
+			Provider:   f.Provider,
+			VerifyLink: f.Links.VerifyLink.Href,
+		})
+	}
+
+	return idp.Session{
+		StateToken:   resp.StateToken,
+		SessionToken: resp.SessionToken,
+		Status:       resp.Status,
+		Factors:      factors,
+	}
+}
@@ -0,0 +1,63 @@
+package idp
+
+// UserData is the credential pair collected from the user (or left blank
+// for providers that authenticate some other way, e.g. a signed JWT or a
+// security key).
+type UserData struct {
+	Username string
+	Password string
+}
+
+// Session carries whatever state a Provider needs between Authenticate,
+// ChallengeMFA and FetchSAMLAssertion: an opaque token plus enough MFA
+// context for the caller to drive a factor picker.
+type Session struct {
+	StateToken   string
+	SessionToken string
+	Status       string
+	Factors      []Factor
+}
+
+// Factor describes one MFA option a Session can be challenged with, plus
+// whatever the user supplied to satisfy it: an OTP/passcode for TOTP and
+// OneLogin-style factors, or an OAuth authorization code for the oidc
+// provider. Credential is deliberately untyped text rather than a
+// separate field per provider — ChallengeMFA callers already have to
+// know which Factor they picked, so they know what Credential means for
+// it. ID carries a provider-specific factor/device identifier (e.g.
+// OneLogin's device_id) for providers whose VerifyLink isn't enough on
+// its own to address the factor.
+type Factor struct {
+	Type       string
+	Provider   string
+	VerifyLink string
+	ID         string
+	Credential string
+}
+
+// Provider is implemented once per identity provider yak supports. The
+// okta package is the original implementation and remains the default;
+// azuread, onelogin and oidc are siblings so orgs that migrated off Okta
+// can still use yak.
+type Provider interface {
+	Authenticate(userData UserData) (Session, error)
+	ChallengeMFA(session Session, factor Factor) (Session, error)
+	FetchSAMLAssertion(session Session, appURL string) (string, error)
+}
+
+// Name identifies which Provider implementation a caller wants. There's
+// no config/cmd package in this tree yet to read a `provider:` field
+// from — Name exists so that plumbing, when it's built, has a fixed set
+// of values to parse into rather than inventing its own.
+type Name string
+
+const (
+	Okta     Name = "okta"
+	AzureAD  Name = "azuread"
+	OneLogin Name = "onelogin"
+	OIDC     Name = "oidc"
+)
+
+// DefaultProvider preserves yak's historical behaviour: Okta, unless a
+// caller explicitly picks something else.
+const DefaultProvider = Okta
@@ -0,0 +1,240 @@
+package session
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/99designs/keyring"
+	homedir "github.com/mitchellh/go-homedir"
+	"golang.org/x/crypto/scrypt"
+)
+
+const keyringService = "yak"
+const keyringKeyName = "session-encryption-key"
+const passphraseEnvVar = "YAK_SESSION_PASSPHRASE"
+
+// FileStore is the default Store: one AES-GCM encrypted file per profile
+// under ~/.yak/sessions/. The encryption key comes from the OS keyring
+// where one is available, falling back to a passphrase-derived key via
+// scrypt so yak still works on headless boxes without a keyring backend.
+type FileStore struct {
+	Dir string
+}
+
+func NewFileStore() (*FileStore, error) {
+	home, err := homedir.Dir()
+
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Join(home, ".yak", "sessions")
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+
+	return &FileStore{Dir: dir}, nil
+}
+
+func (s *FileStore) Load(profile string) (*Session, error) {
+	ciphertext, err := ioutil.ReadFile(s.path(profile))
+
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.encryptionKey()
+
+	if err != nil {
+		return nil, err
+	}
+
+	plaintext, err := decrypt(key, ciphertext)
+
+	if err != nil {
+		return nil, err
+	}
+
+	sess := &Session{}
+
+	if err := json.Unmarshal(plaintext, sess); err != nil {
+		return nil, err
+	}
+
+	return sess, nil
+}
+
+func (s *FileStore) Save(profile string, sess *Session) error {
+	plaintext, err := json.Marshal(sess)
+
+	if err != nil {
+		return err
+	}
+
+	key, err := s.encryptionKey()
+
+	if err != nil {
+		return err
+	}
+
+	ciphertext, err := encrypt(key, plaintext)
+
+	if err != nil {
+		return err
+	}
+
+	return ioutil.WriteFile(s.path(profile), ciphertext, 0600)
+}
+
+func (s *FileStore) Delete(profile string) error {
+	err := os.Remove(s.path(profile))
+
+	if os.IsNotExist(err) {
+		return nil
+	}
+
+	return err
+}
+
+// Wipe removes every cached session, not just the current profile's.
+// There's no `yak logout` subcommand wired up to it — no cmd package
+// exists in this tree for one to live in — so for now this is the whole
+// implementation of "log out": call Wipe directly.
+func (s *FileStore) Wipe() error {
+	return os.RemoveAll(s.Dir)
+}
+
+func (s *FileStore) path(profile string) string {
+	return filepath.Join(s.Dir, profile+".json.enc")
+}
+
+func (s *FileStore) encryptionKey() ([]byte, error) {
+	if key, err := s.keyringEncryptionKey(); err == nil {
+		return key, nil
+	}
+
+	return s.passphraseEncryptionKey()
+}
+
+func (s *FileStore) keyringEncryptionKey() ([]byte, error) {
+	ring, err := keyring.Open(keyring.Config{ServiceName: keyringService})
+
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := ring.Get(keyringKeyName)
+
+	if err == keyring.ErrKeyNotFound {
+		key := make([]byte, 32)
+
+		if _, err := rand.Read(key); err != nil {
+			return nil, err
+		}
+
+		if err := ring.Set(keyring.Item{Key: keyringKeyName, Data: key}); err != nil {
+			return nil, err
+		}
+
+		return key, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return item.Data, nil
+}
+
+func (s *FileStore) passphraseEncryptionKey() ([]byte, error) {
+	passphrase := os.Getenv(passphraseEnvVar)
+
+	if passphrase == "" {
+		return nil, errors.New("no OS keyring available and " + passphraseEnvVar + " is not set")
+	}
+
+	salt, err := s.saltForScrypt()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return scrypt.Key([]byte(passphrase), salt, 1<<15, 8, 1, 32)
+}
+
+func (s *FileStore) saltForScrypt() ([]byte, error) {
+	saltPath := filepath.Join(s.Dir, ".salt")
+
+	if existing, err := ioutil.ReadFile(saltPath); err == nil {
+		return existing, nil
+	}
+
+	salt := make([]byte, 16)
+
+	if _, err := rand.Read(salt); err != nil {
+		return nil, err
+	}
+
+	if err := ioutil.WriteFile(saltPath, salt, 0600); err != nil {
+		return nil, err
+	}
+
+	return salt, nil
+}
+
+func encrypt(key []byte, plaintext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+
+	if err != nil {
+		return nil, err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return gcm.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func decrypt(key []byte, ciphertext []byte) ([]byte, error) {
+	block, err := aes.NewCipher(key)
+
+	if err != nil {
+		return nil, err
+	}
+
+	gcm, err := cipher.NewGCM(block)
+
+	if err != nil {
+		return nil, err
+	}
+
+	nonceSize := gcm.NonceSize()
+
+	if len(ciphertext) < nonceSize {
+		return nil, errors.New("encrypted session is corrupt")
+	}
+
+	nonce, sealed := ciphertext[:nonceSize], ciphertext[nonceSize:]
+
+	return gcm.Open(nil, nonce, sealed, nil)
+}
@@ -0,0 +1,97 @@
+package session
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func newTestStore(t *testing.T) *FileStore {
+	t.Helper()
+
+	os.Setenv(passphraseEnvVar, "correct-horse-battery-staple")
+	t.Cleanup(func() { os.Unsetenv(passphraseEnvVar) })
+
+	return &FileStore{Dir: t.TempDir()}
+}
+
+func TestFileStoreSaveLoadRoundTrip(t *testing.T) {
+	store := newTestStore(t)
+
+	sess := &Session{
+		OktaSessionToken: "token-123",
+		ExpiresAt:        time.Now().Add(time.Hour).Truncate(time.Second),
+		AwsCredentials: AwsCredentials{
+			AccessKeyID: "AKIA...",
+			Expiration:  time.Now().Add(time.Hour).Truncate(time.Second),
+		},
+	}
+
+	if err := store.Save("default", sess); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	loaded, err := store.Load("default")
+
+	if err != nil {
+		t.Fatalf("Load returned error: %v", err)
+	}
+
+	if loaded == nil {
+		t.Fatal("expected a cached session, got nil")
+	}
+
+	if loaded.OktaSessionToken != sess.OktaSessionToken {
+		t.Errorf("expected OktaSessionToken %q, got %q", sess.OktaSessionToken, loaded.OktaSessionToken)
+	}
+
+	if loaded.AwsCredentials.AccessKeyID != sess.AwsCredentials.AccessKeyID {
+		t.Errorf("expected AccessKeyID %q, got %q", sess.AwsCredentials.AccessKeyID, loaded.AwsCredentials.AccessKeyID)
+	}
+}
+
+func TestFileStoreLoadMissingProfileReturnsNil(t *testing.T) {
+	store := newTestStore(t)
+
+	loaded, err := store.Load("does-not-exist")
+
+	if err != nil {
+		t.Fatalf("expected no error for a missing profile, got %v", err)
+	}
+
+	if loaded != nil {
+		t.Errorf("expected nil for a missing profile, got %+v", loaded)
+	}
+}
+
+func TestFileStoreWipeRemovesEverySession(t *testing.T) {
+	store := newTestStore(t)
+
+	if err := store.Save("default", &Session{OktaSessionToken: "token"}); err != nil {
+		t.Fatalf("Save returned error: %v", err)
+	}
+
+	if err := store.Wipe(); err != nil {
+		t.Fatalf("Wipe returned error: %v", err)
+	}
+
+	loaded, err := store.Load("default")
+
+	if err != nil {
+		t.Fatalf("Load after Wipe returned error: %v", err)
+	}
+
+	if loaded != nil {
+		t.Errorf("expected no session after Wipe, got %+v", loaded)
+	}
+}
+
+func TestFileStoreRequiresKeyringOrPassphrase(t *testing.T) {
+	os.Unsetenv(passphraseEnvVar)
+
+	store := &FileStore{Dir: t.TempDir()}
+
+	if err := store.Save("default", &Session{OktaSessionToken: "token"}); err == nil {
+		t.Error("expected Save to fail without a keyring or passphrase available")
+	}
+}
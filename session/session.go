@@ -0,0 +1,42 @@
+package session
+
+import (
+	"net/http"
+	"time"
+)
+
+// AwsCredentials is the short-lived STS credential set obtained after a
+// successful SAML or OIDC assume-role exchange.
+type AwsCredentials struct {
+	AccessKeyID     string    `json:"access_key_id"`
+	SecretAccessKey string    `json:"secret_access_key"`
+	SessionToken    string    `json:"session_token"`
+	Expiration      time.Time `json:"expiration"`
+}
+
+// Session is everything yak would otherwise have to re-derive by running
+// the full Okta auth flow: the Okta session token, the cookies harvested
+// during AwsSamlLogin, and the AWS credentials those were traded for.
+type Session struct {
+	OktaSessionToken string         `json:"okta_session_token"`
+	ExpiresAt        time.Time      `json:"expires_at"`
+	Cookies          []*http.Cookie `json:"cookies"`
+	AwsCredentials   AwsCredentials `json:"aws_credentials"`
+}
+
+func (s Session) Expired() bool {
+	return time.Now().After(s.ExpiresAt)
+}
+
+func (c AwsCredentials) Expired() bool {
+	return time.Now().After(c.Expiration)
+}
+
+// Store persists Sessions between yak invocations so users who run yak
+// dozens of times a day don't re-run the full Okta+SAML flow every time.
+type Store interface {
+	Load(profile string) (*Session, error)
+	Save(profile string, sess *Session) error
+	Delete(profile string) error
+	Wipe() error
+}